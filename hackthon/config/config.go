@@ -0,0 +1,122 @@
+// Package config は本サーバーの起動設定をYAMLファイルと環境変数から読み込む。
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config はサーバー起動に必要な設定全体を表す。
+type Config struct {
+	Server ServerConfig `yaml:"server"`
+	MySQL  MySQLConfig  `yaml:"mysql"`
+	TLS    TLSConfig    `yaml:"tls"`
+	Gemini GeminiConfig `yaml:"gemini"`
+	CORS   CORSConfig   `yaml:"cors"`
+}
+
+type ServerConfig struct {
+	Port string `yaml:"port"`
+}
+
+type MySQLConfig struct {
+	User          string `yaml:"user"`
+	Password      string `yaml:"password"`
+	Host          string `yaml:"host"`
+	Port          string `yaml:"port"`
+	Database      string `yaml:"database"`
+	TLSConfigName string `yaml:"tls_config_name"`
+}
+
+type TLSConfig struct {
+	ServerCAPath   string `yaml:"server_ca_path"`
+	ClientCertPath string `yaml:"client_cert_path"`
+	ClientKeyPath  string `yaml:"client_key_path"`
+}
+
+type GeminiConfig struct {
+	APIKey   string `yaml:"api_key"`
+	Endpoint string `yaml:"endpoint"`
+}
+
+type CORSConfig struct {
+	AllowedOrigin string `yaml:"allowed_origin"`
+}
+
+// DSN は database/sql が受け取れるMySQL接続文字列を組み立てる。
+func (m MySQLConfig) DSN() string {
+	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?tls=%s&parseTime=true",
+		m.User, m.Password, m.Host, m.Port, m.Database, m.TLSConfigName)
+}
+
+// Load はpathのYAMLファイルを読み込み、対応する環境変数で値を上書きした上で
+// 必須項目を検証する。pathが空の場合はCONFIG_PATH環境変数、それも空なら
+// "config.yaml" をカレントディレクトリから読む。
+func Load(path string) (*Config, error) {
+	if path == "" {
+		path = os.Getenv("CONFIG_PATH")
+	}
+	if path == "" {
+		path = "config.yaml"
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("設定ファイル読み込み失敗 (%s): %w", path, err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("設定ファイル解析失敗 (%s): %w", path, err)
+	}
+
+	cfg.applyEnvOverrides()
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func (c *Config) applyEnvOverrides() {
+	overrideIfSet(&c.Server.Port, "SERVER_PORT")
+	overrideIfSet(&c.MySQL.User, "MYSQL_USER")
+	overrideIfSet(&c.MySQL.Password, "MYSQL_PASSWORD")
+	overrideIfSet(&c.MySQL.Host, "MYSQL_HOST")
+	overrideIfSet(&c.MySQL.Port, "MYSQL_PORT")
+	overrideIfSet(&c.MySQL.Database, "MYSQL_DATABASE")
+	overrideIfSet(&c.TLS.ServerCAPath, "TLS_SERVER_CA_PATH")
+	overrideIfSet(&c.TLS.ClientCertPath, "TLS_CLIENT_CERT_PATH")
+	overrideIfSet(&c.TLS.ClientKeyPath, "TLS_CLIENT_KEY_PATH")
+	overrideIfSet(&c.Gemini.APIKey, "GEMINI_API_KEY")
+	overrideIfSet(&c.Gemini.Endpoint, "GEMINI_ENDPOINT")
+	overrideIfSet(&c.CORS.AllowedOrigin, "CORS_ALLOWED_ORIGIN")
+}
+
+func overrideIfSet(field *string, envVar string) {
+	if v := os.Getenv(envVar); v != "" {
+		*field = v
+	}
+}
+
+func (c *Config) validate() error {
+	required := map[string]string{
+		"mysql.user":           c.MySQL.User,
+		"mysql.host":           c.MySQL.Host,
+		"mysql.port":           c.MySQL.Port,
+		"mysql.database":       c.MySQL.Database,
+		"tls.server_ca_path":   c.TLS.ServerCAPath,
+		"tls.client_cert_path": c.TLS.ClientCertPath,
+		"tls.client_key_path":  c.TLS.ClientKeyPath,
+		"gemini.api_key":       c.Gemini.APIKey,
+		"cors.allowed_origin":  c.CORS.AllowedOrigin,
+	}
+	for key, val := range required {
+		if val == "" {
+			return fmt.Errorf("設定項目 %s が未設定です", key)
+		}
+	}
+	return nil
+}
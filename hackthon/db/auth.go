@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	firebase "firebase.google.com/go"
+	"firebase.google.com/go/auth"
+	"google.golang.org/api/option"
+)
+
+type contextKey string
+
+const uidContextKey contextKey = "uid"
+
+var authClient *auth.Client
+
+// initFirebaseAuth は GOOGLE_APPLICATION_CREDENTIALS の認証情報から
+// Firebase Auth クライアントを初期化する。main から registerTLSConfig の
+// 直後に呼び出す想定。
+func initFirebaseAuth() {
+	credPath := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	if credPath == "" {
+		log.Fatal("GOOGLE_APPLICATION_CREDENTIALS が設定されていません")
+	}
+
+	app, err := firebase.NewApp(context.Background(), nil, option.WithCredentialsFile(credPath))
+	if err != nil {
+		log.Fatalf("Firebaseアプリ初期化失敗: %v", err)
+	}
+
+	client, err := app.Auth(context.Background())
+	if err != nil {
+		log.Fatalf("Firebase Authクライアント初期化失敗: %v", err)
+	}
+	authClient = client
+}
+
+// firebaseAuthMiddleware は Authorization: Bearer <idToken> を検証し、
+// 検証済みのUIDをリクエストコンテキストに詰めてから次のハンドラに渡す。
+// 未署名・期限切れトークンは401で弾く。
+func firebaseAuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		if !strings.HasPrefix(header, "Bearer ") {
+			http.Error(w, "認証トークンがありません", http.StatusUnauthorized)
+			return
+		}
+		idToken := strings.TrimPrefix(header, "Bearer ")
+
+		token, err := authClient.VerifyIDToken(r.Context(), idToken)
+		if err != nil {
+			log.Printf("IDトークン検証失敗: %v", err)
+			http.Error(w, "無効な認証トークンです", http.StatusUnauthorized)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), uidContextKey, token.UID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// uidFromContext はミドルウェアが詰めた検証済みUIDを取り出す。
+func uidFromContext(r *http.Request) (string, bool) {
+	uid, ok := r.Context().Value(uidContextKey).(string)
+	return uid, ok
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// sessions テーブル (手動マイグレーション):
+//
+//	CREATE TABLE sessions (
+//	    id               INT AUTO_INCREMENT PRIMARY KEY,
+//	    user_id          INT NOT NULL,
+//	    refresh_hash     CHAR(64) NOT NULL,
+//	    expires_at       DATETIME NOT NULL,
+//	    revoked          BOOLEAN NOT NULL DEFAULT FALSE,
+//	    created_at       DATETIME NOT NULL,
+//	    FOREIGN KEY (user_id) REFERENCES users(id)
+//	);
+
+const (
+	accessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 7 * 24 * time.Hour
+)
+
+const userIDContextKey contextKey = "user_id"
+
+// トークン種別。accessClaims.TokenType に詰めて、アクセストークン用の
+// ミドルウェアとリフレッシュ用のエンドポイントがお互いのトークンを
+// 受け付けてしまわないようにする。
+const (
+	tokenTypeAccess  = "access"
+	tokenTypeRefresh = "refresh"
+)
+
+var jwtSecret []byte
+
+// initJWTSecret は JWT_SECRET 環境変数から署名鍵を読み込む。
+// initFirebaseAuth と同様、main から起動時に呼び出す。
+func initJWTSecret() {
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		log.Fatal("JWT_SECRET が設定されていません")
+	}
+	jwtSecret = []byte(secret)
+}
+
+type accessClaims struct {
+	UserID    int    `json:"user_id"`
+	TokenType string `json:"token_type"`
+	jwt.RegisteredClaims
+}
+
+// issueAccessToken は user_id を詰めた15分有効なHS256署名JWTを発行する。
+func issueAccessToken(userID int) (string, error) {
+	claims := accessClaims{
+		UserID:    userID,
+		TokenType: tokenTypeAccess,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(accessTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(jwtSecret)
+}
+
+// issueRefreshToken はランダム文字列ではなくJWTそのものをリフレッシュトークンとして
+// 発行し、ハッシュを sessions テーブルに保存することで失効(revoke)を可能にする。
+func issueRefreshToken(userID int) (string, error) {
+	claims := accessClaims{
+		UserID:    userID,
+		TokenType: tokenTypeRefresh,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(refreshTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString(jwtSecret)
+	if err != nil {
+		return "", err
+	}
+
+	hash := hashRefreshToken(signed)
+	_, err = db.Exec(
+		`INSERT INTO sessions (user_id, refresh_hash, expires_at, created_at) VALUES (?, ?, ?, ?)`,
+		userID, hash, time.Now().Add(refreshTokenTTL), time.Now(),
+	)
+	if err != nil {
+		return "", fmt.Errorf("セッション保存失敗: %w", err)
+	}
+	return signed, nil
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// parseToken はHS256署名と有効期限だけを検証する。呼び出し側が
+// TokenType を見て、アクセス用・リフレッシュ用の取り違えを防ぐこと。
+func parseToken(tokenString string) (*accessClaims, error) {
+	claims := &accessClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		return jwtSecret, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, fmt.Errorf("無効なトークンです")
+	}
+	return claims, nil
+}
+
+// jwtAuthMiddleware は Authorization: Bearer <accessToken> を検証し、
+// user_id をリクエストコンテキストに詰めてから次のハンドラに渡す。
+func jwtAuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		if !strings.HasPrefix(header, "Bearer ") {
+			http.Error(w, "認証トークンがありません", http.StatusUnauthorized)
+			return
+		}
+		tokenString := strings.TrimPrefix(header, "Bearer ")
+
+		claims, err := parseToken(tokenString)
+		if err != nil || claims.TokenType != tokenTypeAccess {
+			http.Error(w, "無効な認証トークンです", http.StatusUnauthorized)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), userIDContextKey, claims.UserID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// userIDFromContext は jwtAuthMiddleware が詰めた user_id を取り出す。
+func userIDFromContext(r *http.Request) (int, bool) {
+	userID, ok := r.Context().Value(userIDContextKey).(int)
+	return userID, ok
+}
+
+func refreshHandler(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "不正なリクエスト", http.StatusBadRequest)
+		return
+	}
+
+	claims, err := parseToken(payload.RefreshToken)
+	if err != nil || claims.TokenType != tokenTypeRefresh {
+		http.Error(w, "無効なリフレッシュトークンです", http.StatusUnauthorized)
+		return
+	}
+
+	hash := hashRefreshToken(payload.RefreshToken)
+	var expiresAt time.Time
+	var revoked bool
+	err = db.QueryRow(
+		"SELECT expires_at, revoked FROM sessions WHERE user_id = ? AND refresh_hash = ?",
+		claims.UserID, hash,
+	).Scan(&expiresAt, &revoked)
+	if err != nil || revoked || time.Now().After(expiresAt) {
+		http.Error(w, "リフレッシュトークンが無効です", http.StatusUnauthorized)
+		return
+	}
+
+	accessToken, err := issueAccessToken(claims.UserID)
+	if err != nil {
+		log.Printf("アクセストークン発行失敗: %v", err)
+		http.Error(w, "トークン発行エラー", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"access_token": accessToken})
+}
+
+func logoutHandler(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "不正なリクエスト", http.StatusBadRequest)
+		return
+	}
+
+	hash := hashRefreshToken(payload.RefreshToken)
+	_, err := db.Exec("UPDATE sessions SET revoked = TRUE WHERE refresh_hash = ?", hash)
+	if err != nil {
+		http.Error(w, "ログアウト処理エラー", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "ログアウト完了"})
+}
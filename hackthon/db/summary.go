@@ -0,0 +1,94 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// geminiCallTimeout はGemini API呼び出し1回あたりの上限時間。
+// 応答が遅いときにゴルーチンを握りっぱなしにしないためのもの。
+const geminiCallTimeout = 15 * time.Second
+
+func hashReplyContent(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// summaryRateLimiters はユーザーごとのトークンバケットを保持する。
+// 1ユーザーあたり1req/分を上限とする。lastUsedが summaryLimiterIdleTTL を
+// 超えたエントリは定期的に掃除し、接続したユーザー分だけマップが
+// 増え続けないようにする。
+var (
+	summaryRateLimitersMu sync.Mutex
+	summaryRateLimiters   = make(map[int]*summaryLimiterEntry)
+	summarySweepOnce      sync.Once
+)
+
+const (
+	summaryRateLimit            = 1 // req/min per user
+	summaryLimiterIdleTTL       = 10 * time.Minute
+	summaryLimiterSweepInterval = 5 * time.Minute
+)
+
+type summaryLimiterEntry struct {
+	limiter  *rate.Limiter
+	lastUsed time.Time
+}
+
+func summaryLimiterFor(userID int) *rate.Limiter {
+	summaryRateLimitersMu.Lock()
+	defer summaryRateLimitersMu.Unlock()
+
+	summarySweepOnce.Do(startSummaryLimiterSweep)
+
+	entry, ok := summaryRateLimiters[userID]
+	if !ok {
+		entry = &summaryLimiterEntry{limiter: rate.NewLimiter(rate.Every(time.Minute/summaryRateLimit), 1)}
+		summaryRateLimiters[userID] = entry
+	}
+	entry.lastUsed = time.Now()
+	return entry.limiter
+}
+
+// startSummaryLimiterSweep は一定間隔でアイドル状態のリミッタを削除する
+// バックグラウンドゴルーチンを起動する。summarySweepOnce 経由で一度だけ呼ばれる。
+func startSummaryLimiterSweep() {
+	go func() {
+		ticker := time.NewTicker(summaryLimiterSweepInterval)
+		for range ticker.C {
+			summaryRateLimitersMu.Lock()
+			for userID, entry := range summaryRateLimiters {
+				if time.Since(entry.lastUsed) > summaryLimiterIdleTTL {
+					delete(summaryRateLimiters, userID)
+				}
+			}
+			summaryRateLimitersMu.Unlock()
+		}
+	}()
+}
+
+// summaryRateLimitMiddleware は認証済みuser_idごとに要約リクエストを
+// 1req/分に制限する。超過時は429とRetry-Afterヘッダを返す。
+func summaryRateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := userIDFromContext(r)
+		if !ok {
+			http.Error(w, "認証情報がありません", http.StatusUnauthorized)
+			return
+		}
+
+		if !summaryLimiterFor(userID).Allow() {
+			w.Header().Set("Retry-After", strconv.Itoa(int(time.Minute.Seconds())))
+			http.Error(w, "リクエストが多すぎます。しばらくしてから再試行してください", http.StatusTooManyRequests)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsPongWait   = 60 * time.Second
+	wsPingPeriod = 30 * time.Second
+	wsWriteWait  = 10 * time.Second
+)
+
+// event はストリーム経由でクライアントに配信するイベントの共通形。
+type event struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// hub は接続中クライアントのチャンネル集合を保持するプロセス内pub/sub。
+// サブスクライバ一覧はRWMutexで保護する。
+type hub struct {
+	mu          sync.RWMutex
+	subscribers map[chan event]struct{}
+}
+
+var streamHub = &hub{subscribers: make(map[chan event]struct{})}
+
+func (h *hub) subscribe() chan event {
+	ch := make(chan event, 16)
+	h.mu.Lock()
+	h.subscribers[ch] = struct{}{}
+	h.mu.Unlock()
+	return ch
+}
+
+func (h *hub) unsubscribe(ch chan event) {
+	h.mu.Lock()
+	delete(h.subscribers, ch)
+	h.mu.Unlock()
+	close(ch)
+}
+
+// publish は全サブスクライバに非ブロッキングで配信する。受信側のバッファが
+// 詰まっているクライアントは詰まり分を捨てて配信全体を遅らせない。
+func (h *hub) publish(evt event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for ch := range h.subscribers {
+		select {
+		case ch <- evt:
+		default:
+			log.Printf("WebSocket配信スキップ（バッファ詰まり）: %s", evt.Type)
+		}
+	}
+}
+
+// wsUpgrader は corsMiddleware と同じ許可オリジンだけWebSocket接続を許す。
+var wsUpgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool {
+		return r.Header.Get("Origin") == cfg.CORS.AllowedOrigin
+	},
+}
+
+// streamHandler は /api/stream をWebSocketにアップグレードし、streamHub に
+// 届いたイベントをそのままクライアントへ転送する。30秒おきのping/pongで
+// 生存確認し、アイドルタイムアウトと切断時のgoroutine終了を保証する。
+func streamHandler(w http.ResponseWriter, r *http.Request) {
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("WebSocketアップグレード失敗: %v", err)
+		return
+	}
+
+	ch := streamHub.subscribe()
+	done := make(chan struct{})
+
+	go readPump(conn, done)
+	writePump(conn, ch, done)
+
+	streamHub.unsubscribe(ch)
+}
+
+// readPump はクライアントからのpongやクローズフレームだけを処理する。
+// アイドルタイムアウトを超えると読み取りがエラーになり、doneがcloseされる。
+func readPump(conn *websocket.Conn, done chan struct{}) {
+	defer close(done)
+	conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		return nil
+	})
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+func writePump(conn *websocket.Conn, ch chan event, done chan struct{}) {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer func() {
+		ticker.Stop()
+		conn.Close()
+	}()
+
+	for {
+		select {
+		case evt := <-ch:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteJSON(evt); err != nil {
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// publishEvent はDB書き込み成功後にハンドラから呼ぶ薄いヘルパー。
+func publishEvent(eventType string, data interface{}) {
+	streamHub.publish(event{Type: eventType, Data: data})
+}